@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GameConfig is the set of structured options a student can pick when
+// starting a game, e.g. `/game number mode=timed difficulty=hard
+// max_points=50 obstacles=3`. It's POSTed as the body of the game server's
+// start endpoint and stored on the GameSession so later help requests can
+// remind the LLM what the student chose.
+type GameConfig struct {
+	Name       string `json:"name"`
+	Mode       string `json:"mode,omitempty"`
+	Difficulty string `json:"difficulty,omitempty"`
+	MaxPoints  int    `json:"maxPoints,omitempty"`
+	Obstacles  int    `json:"obstacles,omitempty"`
+	Width      int    `json:"width,omitempty"`
+	Height     int    `json:"height,omitempty"`
+}
+
+// configUsage is shown whenever a student supplies an option we can't parse.
+const configUsage = "Usage: /game <gamename> [mode=...] [difficulty=...] [max_points=N] [obstacles=N] [width=N] [height=N]\nSee available options with `/game list`."
+
+// parseGameConfig turns the `key=value` options typed after the game name
+// into a GameConfig, rejecting anything it doesn't recognize so typos don't
+// silently do nothing.
+func parseGameConfig(gameName string, args []string) (GameConfig, error) {
+	config := GameConfig{Name: gameName}
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return GameConfig{}, fmt.Errorf("invalid option %q, expected key=value", arg)
+		}
+
+		switch key {
+		case "mode":
+			config.Mode = value
+		case "difficulty":
+			config.Difficulty = value
+		case "max_points":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return GameConfig{}, fmt.Errorf("max_points must be a number")
+			}
+			config.MaxPoints = n
+		case "obstacles":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return GameConfig{}, fmt.Errorf("obstacles must be a number")
+			}
+			config.Obstacles = n
+		case "width":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return GameConfig{}, fmt.Errorf("width must be a number")
+			}
+			config.Width = n
+		case "height":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return GameConfig{}, fmt.Errorf("height must be a number")
+			}
+			config.Height = n
+		default:
+			return GameConfig{}, fmt.Errorf("unknown option %q", key)
+		}
+	}
+	return config, nil
+}
+
+// summary renders the chosen options for display, e.g. in the LLM prompt.
+func (c GameConfig) summary() string {
+	var parts []string
+	if c.Mode != "" {
+		parts = append(parts, fmt.Sprintf("mode=%s", c.Mode))
+	}
+	if c.Difficulty != "" {
+		parts = append(parts, fmt.Sprintf("difficulty=%s", c.Difficulty))
+	}
+	if c.MaxPoints != 0 {
+		parts = append(parts, fmt.Sprintf("max_points=%d", c.MaxPoints))
+	}
+	if c.Obstacles != 0 {
+		parts = append(parts, fmt.Sprintf("obstacles=%d", c.Obstacles))
+	}
+	if c.Width != 0 {
+		parts = append(parts, fmt.Sprintf("width=%d", c.Width))
+	}
+	if c.Height != 0 {
+		parts = append(parts, fmt.Sprintf("height=%d", c.Height))
+	}
+	return strings.Join(parts, " ")
+}
+
+// GameListEntry describes one game's available modes and difficulties, as
+// returned by GET /game/list.
+type GameListEntry struct {
+	Name         string   `json:"name"`
+	Modes        []string `json:"modes"`
+	Difficulties []string `json:"difficulties"`
+}
+
+// GameListResponse from the Python server.
+type GameListResponse struct {
+	Games []GameListEntry `json:"games"`
+	Error string          `json:"error"`
+}
+
+// listGames fetches the available games and their options from the Python
+// game server.
+func (b *Bot) listGames() (*GameListResponse, error) {
+	url := fmt.Sprintf("%s/game/list", b.config.GameServerURL)
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to game server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var listResp GameListResponse
+	if err := json.Unmarshal(body, &listResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &listResp, nil
+}
+
+// renderGameList formats the available games as a Markdown list for an
+// ephemeral response.
+func renderGameList(list *GameListResponse) string {
+	if list.Error != "" {
+		return fmt.Sprintf("❌ %s", list.Error)
+	}
+	if len(list.Games) == 0 {
+		return "No games are available right now."
+	}
+
+	var b strings.Builder
+	b.WriteString("**Available games:**\n")
+	for _, game := range list.Games {
+		fmt.Fprintf(&b, "- `%s`", game.Name)
+		if len(game.Modes) > 0 {
+			fmt.Fprintf(&b, " — modes: %s", strings.Join(game.Modes, ", "))
+		}
+		if len(game.Difficulties) > 0 {
+			fmt.Fprintf(&b, ", difficulties: %s", strings.Join(game.Difficulties, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
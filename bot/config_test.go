@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+func TestParseGameConfig(t *testing.T) {
+	config, err := parseGameConfig("number", []string{"mode=timed", "difficulty=hard", "max_points=50", "obstacles=3", "width=10", "height=20"})
+	if err != nil {
+		t.Fatalf("parseGameConfig returned error: %v", err)
+	}
+	want := GameConfig{
+		Name:       "number",
+		Mode:       "timed",
+		Difficulty: "hard",
+		MaxPoints:  50,
+		Obstacles:  3,
+		Width:      10,
+		Height:     20,
+	}
+	if config != want {
+		t.Fatalf("parseGameConfig = %+v, want %+v", config, want)
+	}
+}
+
+func TestParseGameConfigDefaults(t *testing.T) {
+	config, err := parseGameConfig("number", nil)
+	if err != nil {
+		t.Fatalf("parseGameConfig returned error: %v", err)
+	}
+	if config != (GameConfig{Name: "number"}) {
+		t.Fatalf("parseGameConfig = %+v, want just the name set", config)
+	}
+}
+
+func TestParseGameConfigInvalidOption(t *testing.T) {
+	if _, err := parseGameConfig("number", []string{"notakeyvalue"}); err == nil {
+		t.Fatal("expected an error for an option without key=value")
+	}
+}
+
+func TestParseGameConfigUnknownOption(t *testing.T) {
+	if _, err := parseGameConfig("number", []string{"color=blue"}); err == nil {
+		t.Fatal("expected an error for an unknown option")
+	}
+}
+
+func TestParseGameConfigBadNumber(t *testing.T) {
+	if _, err := parseGameConfig("number", []string{"max_points=notanumber"}); err == nil {
+		t.Fatal("expected an error for a non-numeric max_points")
+	}
+}
+
+func TestGameConfigSummary(t *testing.T) {
+	config := GameConfig{Name: "number", Mode: "timed", MaxPoints: 50}
+	want := "mode=timed max_points=50"
+	if got := config.summary(); got != want {
+		t.Fatalf("summary() = %q, want %q", got, want)
+	}
+	if got := (GameConfig{Name: "number"}).summary(); got != "" {
+		t.Fatalf("summary() = %q, want empty for an unconfigured game", got)
+	}
+}
@@ -1,45 +1,14 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"os"
 )
 
-// LLMConfig holds configuration for the LLM service
-type LLMConfig struct {
-	OpenRouterAPIKey string
-	Model            string
-}
-
-// OpenRouterRequest represents a request to OpenRouter API
-type OpenRouterRequest struct {
-	Model    string          `json:"model"`
-	Messages []OpenRouterMsg `json:"messages"`
-}
-
-// OpenRouterMsg represents a message in the OpenRouter API
-type OpenRouterMsg struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
-// OpenRouterResponse represents a response from OpenRouter API
-type OpenRouterResponse struct {
-	Choices []struct {
-		Message struct {
-			Content string `json:"content"`
-		} `json:"message"`
-	} `json:"choices"`
-	Error *struct {
-		Message string `json:"message"`
-	} `json:"error"`
-}
-
 // SourceResponse from Python server
 type SourceResponse struct {
 	Game   string `json:"game"`
@@ -64,9 +33,10 @@ type MattermostPost struct {
 // provideHelp handles requests for checking the game source and getting LLM advice
 func (b *Bot) provideHelp(channelID, userQuestion string) (string, error) {
 	// Check if there's an active game in this channel
-	b.mu.RLock()
-	session := b.sessions[channelID]
-	b.mu.RUnlock()
+	session, err := b.sessions.Get(channelID)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't load session: %v", err)
+	}
 
 	if session == nil || !session.Active {
 		return "", fmt.Errorf("No active game in this channel. Start a game with `/game <gamename>` first!")
@@ -87,8 +57,18 @@ func (b *Bot) provideHelp(channelID, userQuestion string) (string, error) {
 		recentMessages = []string{}
 	}
 
+	// If a playbook is guiding this session, add its current step's prompt
+	// to the generic tutor prompt, so guidance stays on-script.
+	var stepPrompt string
+	if session.PlaybookID != "" {
+		stepPrompt, err = b.playbooks.CurrentPrompt(session)
+		if err != nil {
+			log.Printf("Error loading playbook prompt: %v", err)
+		}
+	}
+
 	// Get LLM response
-	llmResponse, err := b.getLLMResponse(session.GameName, gameSource, recentMessages, userQuestion)
+	llmResponse, err := b.getLLMResponse(channelID, session.GameName, session.Config, stepPrompt, gameSource, recentMessages, userQuestion)
 	if err != nil {
 		log.Printf("Error getting LLM response: %v", err)
 		return "", fmt.Errorf("Error from AI assistant: %v", err)
@@ -174,13 +154,9 @@ func (b *Bot) getRecentMessages(channelID string, count int) ([]string, error) {
 	return messages, nil
 }
 
-// getLLMResponse calls OpenRouter to get coding assistance
-func (b *Bot) getLLMResponse(gameName, gameSource string, recentMessages []string, userQuestion string) (string, error) {
-	apiKey := os.Getenv("OPENROUTER_API_KEY")
-	if apiKey == "" {
-		return "", fmt.Errorf("OPENROUTER_API_KEY environment variable not set")
-	}
-
+// getLLMResponse asks the LLM client for coding assistance, rate limited and
+// retried per channel.
+func (b *Bot) getLLMResponse(channelID, gameName string, config GameConfig, stepPrompt string, gameSource string, recentMessages []string, userQuestion string) (string, error) {
 	// Build context from recent messages
 	var contextMessages string
 	if len(recentMessages) > 0 {
@@ -194,6 +170,11 @@ func (b *Bot) getLLMResponse(gameName, gameSource string, recentMessages []strin
 		userQuestion = fmt.Sprintf(`\n**Student's Question:**\n"%s"\n`, userQuestion)
 	}
 
+	var configSummary string
+	if summary := config.summary(); summary != "" {
+		configSummary = fmt.Sprintf("\n**Chosen settings:** %s\n", summary)
+	}
+
 	// Build the system prompt
 	systemPrompt := `You are a friendly coding tutor helping an absolute beginner learn to program Python through a simple game.
 
@@ -204,9 +185,13 @@ Your role is to:
 
 Remember: The student is a complete beginner. Assume they know nothing about programming.`
 
+	if stepPrompt != "" {
+		systemPrompt += "\n\nThe student is on a guided lesson step with this additional guidance: " + stepPrompt
+	}
+
 	// Build the user message with context
 	userMessage := fmt.Sprintf(`**Current Game: %s**
-
+%s
 Here is the game's Python code:
 %s
 %s
@@ -218,56 +203,10 @@ Please help this beginner understand their question. Remember to:
 - Keep it short and simple
 - Encourage them to experiment and learn`,
 		gameName,
+		configSummary,
 		"```python\n"+gameSource+"\n```",
 		contextMessages,
 		userQuestion)
 
-	// Build the request
-	reqBody := OpenRouterRequest{
-		Model: "google/gemini-2.5-flash",
-		Messages: []OpenRouterMsg{
-			{Role: "system", Content: systemPrompt},
-			{Role: "user", Content: userMessage},
-		},
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest(http.MethodPost, "https://openrouter.ai/api/v1/chat/completions", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("HTTP-Referer", "https://github.com/shishberg/mattergame")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to call OpenRouter: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	var llmResp OpenRouterResponse
-	if err := json.Unmarshal(body, &llmResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if llmResp.Error != nil {
-		return "", fmt.Errorf("OpenRouter error: %s", llmResp.Error.Message)
-	}
-
-	if len(llmResp.Choices) == 0 {
-		return "", fmt.Errorf("no response from AI")
-	}
-
-	return llmResp.Choices[0].Message.Content, nil
+	return b.llm.Complete(context.Background(), channelID, systemPrompt, userMessage)
 }
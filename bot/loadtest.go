@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shishberg/mattergame/internal/loadtest"
+)
+
+// loadtestUsage is shown when a student supplies an option we can't parse.
+const loadtestUsage = "Usage: /game loadtest sessions=N moves=N game=<name> [rate=N/s] [move_script=a,b,c]"
+
+// parseLoadtestConfig turns the `key=value` options typed after `loadtest`
+// into a loadtest.Config.
+func parseLoadtestConfig(args []string) (loadtest.Config, error) {
+	cfg := loadtest.Config{Sessions: 10, Moves: 10, Rate: 2}
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return loadtest.Config{}, fmt.Errorf("invalid option %q, expected key=value", arg)
+		}
+
+		switch key {
+		case "game":
+			cfg.Game = value
+		case "sessions":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return loadtest.Config{}, fmt.Errorf("sessions must be a number")
+			}
+			cfg.Sessions = n
+		case "moves":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return loadtest.Config{}, fmt.Errorf("moves must be a number")
+			}
+			cfg.Moves = n
+		case "rate":
+			rps, err := strconv.ParseFloat(strings.TrimSuffix(value, "/s"), 64)
+			if err != nil {
+				return loadtest.Config{}, fmt.Errorf("rate must look like 2 or 2/s")
+			}
+			cfg.Rate = rps
+		case "move_script":
+			cfg.MoveScript = strings.Split(value, ",")
+		default:
+			return loadtest.Config{}, fmt.Errorf("unknown option %q", key)
+		}
+	}
+	if cfg.Game == "" {
+		return loadtest.Config{}, fmt.Errorf("game is required")
+	}
+	return cfg, nil
+}
+
+// runLoadtest runs a synthetic burst of sessions against the game server and
+// posts a Markdown summary to the invoking channel. It's gated behind
+// ENABLE_LOADTEST so students can't accidentally hammer the game server.
+func (b *Bot) runLoadtest(channelID string, cfg loadtest.Config) {
+	runner := loadtest.NewRunner(cfg.Rate)
+
+	startFn := func(game string) (string, error) {
+		resp, err := b.startGame(GameConfig{Name: game})
+		if err != nil {
+			return "", err
+		}
+		return resp.Error, nil
+	}
+	moveFn := func(game, move string) (string, error) {
+		resp, err := b.processMove(game, move)
+		if err != nil {
+			return "", err
+		}
+		return resp.Error, nil
+	}
+
+	result := runner.Run(context.Background(), cfg, startFn, moveFn)
+	b.postMessage(channelID, renderLoadtestResult(result))
+}
+
+// renderLoadtestResult formats a loadtest.Result as a Markdown summary.
+func renderLoadtestResult(r *loadtest.Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**📊 Loadtest: %s** (%d sessions × %d moves, %.1f/s, took %s)\n\n",
+		r.Config.Game, r.Config.Sessions, r.Config.Moves, r.Config.Rate, r.Duration.Round(10e6))
+
+	b.WriteString("| | count | min | p50 | p95 | p99 | max |\n")
+	b.WriteString("|---|---|---|---|---|---|---|\n")
+	writeHistogramRow(&b, "start", r.StartLatency)
+	writeHistogramRow(&b, "move", r.MoveLatency)
+
+	if len(r.Errors) == 0 {
+		b.WriteString("\nNo errors. ✅\n")
+		return b.String()
+	}
+
+	b.WriteString("\n**Errors:**\n")
+	for msg, count := range r.Errors {
+		fmt.Fprintf(&b, "- `%s`: %d\n", msg, count)
+	}
+	return b.String()
+}
+
+func writeHistogramRow(b *strings.Builder, label string, h loadtest.Histogram) {
+	fmt.Fprintf(b, "| %s | %d | %s | %s | %s | %s | %s |\n",
+		label, h.Count, h.Min.Round(10e6), h.P50.Round(10e6), h.P95.Round(10e6), h.P99.Round(10e6), h.Max.Round(10e6))
+}
@@ -8,29 +8,48 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
-	"sync"
+	"time"
+
+	"github.com/shishberg/mattergame/bot/mmclient"
+	"github.com/shishberg/mattergame/internal/llm"
 )
 
 // Config holds bot configuration
 type Config struct {
-	MattermostURL string
-	BotToken      string
-	GameServerURL string
-	ListenAddr    string
+	MattermostURL  string
+	BotToken       string
+	GameServerURL  string
+	ListenAddr     string
+	SessionDBPath  string
+	UseWebSocket   bool
+	EnableLoadtest bool
+	PlaybooksDir   string
 }
 
 // GameSession tracks active games per channel
 type GameSession struct {
-	GameName string
-	Active   bool
+	GameName  string
+	Active    bool
+	StartedAt int64
+	StartedBy string
+	Config    GameConfig
+
+	// PlaybookID and StepIndex track progress through a scripted lesson, if
+	// the session was started with `/game playbook <name>`.
+	PlaybookID string
+	StepIndex  int
 }
 
 // Bot handles Mattermost interactions
 type Bot struct {
-	config   Config
-	sessions map[string]*GameSession // channelID -> session
-	mu       sync.RWMutex
+	config    Config
+	sessions  SessionStore
+	mm        *mmclient.Client
+	botUserID string
+	llm       *llm.Client
+	playbooks *PlaybookRunService
 }
 
 // GameResponse from Python server
@@ -62,30 +81,116 @@ type Post struct {
 
 func main() {
 	config := Config{
-		MattermostURL: getEnv("MATTERMOST_URL", "https://your-mattermost.com"),
-		BotToken:      getEnv("MATTERMOST_BOT_TOKEN", ""),
-		GameServerURL: getEnv("GAME_SERVER_URL", "http://localhost:6000"),
-		ListenAddr:    getEnv("LISTEN_ADDR", ":6001"),
+		MattermostURL:  getEnv("MATTERMOST_URL", "https://your-mattermost.com"),
+		BotToken:       getEnv("MATTERMOST_BOT_TOKEN", ""),
+		GameServerURL:  getEnv("GAME_SERVER_URL", "http://localhost:6000"),
+		ListenAddr:     getEnv("LISTEN_ADDR", ":6001"),
+		SessionDBPath:  getEnv("SESSION_DB_PATH", ""),
+		UseWebSocket:   getEnv("USE_WEBSOCKET", "") == "true",
+		EnableLoadtest: getEnv("ENABLE_LOADTEST", "") == "true",
+		PlaybooksDir:   getEnv("PLAYBOOKS_DIR", "playbooks"),
 	}
 
 	if config.BotToken == "" {
 		log.Fatal("MATTERMOST_BOT_TOKEN environment variable is required")
 	}
 
+	sessions, err := newSessionStore(config.SessionDBPath)
+	if err != nil {
+		log.Fatalf("failed to open session store: %v", err)
+	}
+
 	bot := &Bot{
 		config:   config,
-		sessions: make(map[string]*GameSession),
+		sessions: sessions,
+		llm: llm.New(llm.Config{
+			APIKey:           os.Getenv("OPENROUTER_API_KEY"),
+			Model:            getEnv("OPENROUTER_MODEL", "google/gemini-2.5-flash"),
+			RPS:              getEnvFloat("OPENROUTER_RPS", 1),
+			Burst:            getEnvInt("OPENROUTER_BURST", 3),
+			BreakerThreshold: getEnvInt("OPENROUTER_BREAKER_THRESHOLD", 5),
+		}),
+		playbooks: newPlaybookRunService(config.PlaybooksDir),
+	}
+
+	active, err := bot.sessions.List()
+	if err != nil {
+		log.Fatalf("failed to load existing sessions: %v", err)
+	}
+	for _, session := range active {
+		if session.Active {
+			log.Printf("🔄 resuming session: game=%s started_by=%s", session.GameName, session.StartedBy)
+		}
+	}
+
+	if config.UseWebSocket {
+		bot.startWebSocket()
 	}
 
 	http.HandleFunc("/game", bot.handleSlashCommand)
 	http.HandleFunc("/webhook", bot.handleWebhook)
 	http.HandleFunc("/health", handleHealth)
+	http.HandleFunc("/metrics", bot.handleMetrics)
 
 	log.Printf("🤖 Bot starting on %s", config.ListenAddr)
 	log.Printf("🎮 Game server: %s", config.GameServerURL)
 	log.Fatal(http.ListenAndServe(config.ListenAddr, nil))
 }
 
+// startWebSocket connects to Mattermost's realtime API and starts
+// dispatching events to the bot in the background, replacing the need for a
+// per-channel outgoing webhook.
+func (b *Bot) startWebSocket() {
+	mm := mmclient.New(b.config.MattermostURL, b.config.BotToken)
+
+	me, err := mm.Me()
+	if err != nil {
+		log.Fatalf("failed to authenticate websocket client: %v", err)
+	}
+	b.mm = mm
+	b.botUserID = me.ID
+
+	teams, err := mm.Teams()
+	if err != nil {
+		log.Fatalf("failed to discover teams: %v", err)
+	}
+	var channelCount int
+	for _, team := range teams {
+		channels, err := mm.Channels(team.ID)
+		if err != nil {
+			log.Printf("failed to discover channels for team %s: %v", team.Name, err)
+			continue
+		}
+		channelCount += len(channels)
+	}
+	log.Printf("📡 discovered %d team(s), %d channel(s)", len(teams), channelCount)
+
+	go func() {
+		if err := mm.Listen(nil, b.handleMMEvent); err != nil {
+			log.Printf("mmclient: listener exited: %v", err)
+		}
+	}()
+}
+
+// handleMMEvent dispatches a realtime event from the Mattermost websocket to
+// the same processing path as the legacy /webhook handler.
+func (b *Bot) handleMMEvent(event mmclient.Event) {
+	switch event.Event {
+	case "posted", "post_edited":
+		post, err := event.Post()
+		if err != nil {
+			log.Printf("Error unmarshalling post event: %v", err)
+			return
+		}
+		if post.UserID == b.botUserID {
+			return
+		}
+		b.processChannelMessage(post.ChannelID, post.Message)
+	case "channel_created":
+		log.Printf("📣 channel created: %s", event.Broadcast.ChannelID)
+	}
+}
+
 // handleSlashCommand handles /game commands
 func (b *Bot) handleSlashCommand(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -113,15 +218,92 @@ func (b *Bot) handleSlashCommand(w http.ResponseWriter, r *http.Request) {
 		Text:        r.FormValue("text"),
 	}
 
-	// Parse command: /game <gamename>
-	gameName := strings.TrimSpace(cmd.Text)
-	if gameName == "" {
-		b.respondEphemeral(w, "Usage: /game <gamename>\nExample: /game number")
+	// Parse command: /game <gamename> [mode=... difficulty=... ...]
+	fields := strings.Fields(cmd.Text)
+	if len(fields) == 0 {
+		b.respondEphemeral(w, "Usage: /game <gamename>\nExample: /game number\n"+configUsage)
+		return
+	}
+
+	if fields[0] == "list" {
+		list, err := b.listGames()
+		if err != nil {
+			b.respondEphemeral(w, fmt.Sprintf("❌ Error listing games: %v", err))
+			return
+		}
+		b.respondEphemeral(w, renderGameList(list))
+		return
+	}
+
+	if fields[0] == "loadtest" {
+		if !b.config.EnableLoadtest {
+			b.respondEphemeral(w, "❌ Loadtesting is disabled on this bot. Set ENABLE_LOADTEST=true to enable it.")
+			return
+		}
+		cfg, err := parseLoadtestConfig(fields[1:])
+		if err != nil {
+			b.respondEphemeral(w, fmt.Sprintf("❌ %v\n💡 %s", err, loadtestUsage))
+			return
+		}
+		go b.runLoadtest(cmd.ChannelID, cfg)
+		b.respondEphemeral(w, fmt.Sprintf("🏃 Running loadtest: %d sessions × %d moves against `%s`...", cfg.Sessions, cfg.Moves, cfg.Game))
+		return
+	}
+
+	if fields[0] == "playbook" {
+		if len(fields) < 2 {
+			b.respondEphemeral(w, "Usage: /game playbook <name>")
+			return
+		}
+		msg, err := b.startPlaybook(cmd, fields[1])
+		if err != nil {
+			b.respondEphemeral(w, fmt.Sprintf("❌ Error starting playbook: %v", err))
+			return
+		}
+		b.respondInChannel(w, msg)
+		return
+	}
+
+	if fields[0] == "skip" {
+		msg, err := b.skipPlaybookStep(cmd.ChannelID)
+		if err != nil {
+			b.respondEphemeral(w, fmt.Sprintf("❌ %v", err))
+			return
+		}
+		b.respondInChannel(w, msg)
+		return
+	}
+
+	if fields[0] == "reset" {
+		msg, err := b.resetPlaybookStep(cmd.ChannelID)
+		if err != nil {
+			b.respondEphemeral(w, fmt.Sprintf("❌ %v", err))
+			return
+		}
+		b.respondInChannel(w, msg)
+		return
+	}
+
+	if fields[0] == "help" {
+		question := strings.Join(fields[1:], " ")
+		msg, err := b.provideHelp(cmd.ChannelID, question)
+		if err != nil {
+			b.respondEphemeral(w, fmt.Sprintf("❌ %v", err))
+			return
+		}
+		b.respondInChannel(w, msg)
+		return
+	}
+
+	gameName := fields[0]
+	config, err := parseGameConfig(gameName, fields[1:])
+	if err != nil {
+		b.respondEphemeral(w, fmt.Sprintf("❌ %v\n💡 %s", err, configUsage))
 		return
 	}
 
 	// Start the game
-	response, err := b.startGame(gameName)
+	response, err := b.startGame(config)
 	if err != nil {
 		b.respondEphemeral(w, fmt.Sprintf("❌ Error starting game: %v", err))
 		return
@@ -137,24 +319,40 @@ func (b *Bot) handleSlashCommand(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Store session
-	b.mu.Lock()
-	b.sessions[cmd.ChannelID] = &GameSession{
-		GameName: gameName,
-		Active:   true,
+	session := &GameSession{
+		GameName:  gameName,
+		Active:    true,
+		StartedAt: time.Now().Unix(),
+		StartedBy: cmd.UserID,
+		Config:    config,
+	}
+	if err := b.sessions.Put(cmd.ChannelID, session); err != nil {
+		log.Printf("Error saving session: %v", err)
+		b.respondEphemeral(w, fmt.Sprintf("❌ Error saving session: %v", err))
+		return
 	}
-	b.mu.Unlock()
 
 	// Respond in channel
 	b.respondInChannel(w, fmt.Sprintf("**Starting game: %s**\n\n%s", gameName, response.Message))
 }
 
-// handleWebhook handles messages in channels with active games
+// handleWebhook handles messages in channels with active games. This is the
+// legacy path driven by a per-channel outgoing webhook; prefer USE_WEBSOCKET
+// so operators don't need to configure one. Kept as a fallback for bots
+// that can't hold a websocket connection to Mattermost.
 func (b *Bot) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	// The websocket listener already processes every message; handling this
+	// too would double up game moves and LLM calls.
+	if b.config.UseWebSocket {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
 	// Parse form data (outgoing webhooks send application/x-www-form-urlencoded by default)
 	if err := r.ParseForm(); err != nil {
 		log.Printf("Error parsing form: %v", err)
@@ -168,24 +366,45 @@ func (b *Bot) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	text := r.FormValue("text")
 	// token := r.FormValue("token")
 
+	// Don't respond to bot's own messages
+	// (You may need to check if userID matches bot's user ID)
+
+	b.processChannelMessage(channelID, text)
+	w.WriteHeader(http.StatusOK)
+}
+
+// processChannelMessage handles a single channel message against any active
+// game session, posting the result back to the channel. It's the shared path
+// for both the legacy /webhook handler and realtime websocket events.
+func (b *Bot) processChannelMessage(channelID, text string) {
 	// Check if there's an active game in this channel
-	b.mu.RLock()
-	session := b.sessions[channelID]
-	b.mu.RUnlock()
+	session, err := b.sessions.Get(channelID)
+	if err != nil {
+		log.Printf("Error loading session: %v", err)
+		return
+	}
 
 	if session == nil || !session.Active {
-		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	// Don't respond to bot's own messages
-	// (You may need to check if userID matches bot's user ID)
+	// Treat "help" or "help <question>" as a request for the LLM tutor
+	// instead of a move.
+	if text == "help" || strings.HasPrefix(text, "help ") {
+		question := strings.TrimSpace(strings.TrimPrefix(text, "help"))
+		msg, err := b.provideHelp(channelID, question)
+		if err != nil {
+			b.postMessage(channelID, fmt.Sprintf("❌ %v", err))
+			return
+		}
+		b.postMessage(channelID, msg)
+		return
+	}
 
 	// Process the move
 	response, err := b.processMove(session.GameName, text)
 	if err != nil {
 		b.postMessage(channelID, fmt.Sprintf("❌ Error: %v", err))
-		w.WriteHeader(http.StatusOK)
 		return
 	}
 
@@ -195,20 +414,39 @@ func (b *Bot) handleWebhook(w http.ResponseWriter, r *http.Request) {
 			msg += fmt.Sprintf("\n💡 %s", response.Help)
 		}
 		b.postMessage(channelID, msg)
-		w.WriteHeader(http.StatusOK)
 		return
 	}
 
 	// Post response
 	b.postMessage(channelID, response.Message)
-	w.WriteHeader(http.StatusOK)
+
+	// Advance the playbook, if one is active, and share the next hint.
+	if session.PlaybookID != "" {
+		advanced, hint, err := b.playbooks.Advance(session, response.Message)
+		if err != nil {
+			log.Printf("Error advancing playbook: %v", err)
+			return
+		}
+		if advanced {
+			if err := b.sessions.Put(channelID, session); err != nil {
+				log.Printf("Error saving session: %v", err)
+			}
+			b.postMessage(channelID, hint)
+		}
+	}
 }
 
-// startGame calls the Python game server to start a game
-func (b *Bot) startGame(gameName string) (*GameResponse, error) {
-	url := fmt.Sprintf("%s/game/%s/start", b.config.GameServerURL, gameName)
+// startGame calls the Python game server to start a game with the given
+// configuration.
+func (b *Bot) startGame(config GameConfig) (*GameResponse, error) {
+	url := fmt.Sprintf("%s/game/%s/start", b.config.GameServerURL, config.Name)
+
+	jsonData, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal game config: %w", err)
+	}
 
-	resp, err := http.Post(url, "application/json", nil)
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to game server: %w", err)
 	}
@@ -317,9 +555,38 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintln(w, "OK")
 }
 
+// handleMetrics exposes the LLM client's request counters so operators can
+// see abuse (throttling, breaker trips) without digging through logs.
+func (b *Bot) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	m := b.llm.Metrics()
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "llm_requests_total %d\n", m.Requests)
+	fmt.Fprintf(w, "llm_retries_total %d\n", m.Retries)
+	fmt.Fprintf(w, "llm_throttled_total %d\n", m.Throttled)
+	fmt.Fprintf(w, "llm_breaker_open_total %d\n", m.BreakerOpen)
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,219 @@
+// Package mmclient is a small Mattermost client that discovers team/channel
+// membership over the REST API and then follows realtime events over the
+// `/api/v4/websocket` endpoint, modeled on matterbridge's matterclient.
+package mmclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Team is a Mattermost team, as returned by /api/v4/users/me/teams.
+type Team struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Channel is a Mattermost channel, as returned by
+// /api/v4/users/me/teams/{teamId}/channels.
+type Channel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Post mirrors the subset of a Mattermost post we care about, unmarshalled
+// from the nested JSON string in an Event's Data["post"] field.
+type Post struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channel_id"`
+	UserID    string `json:"user_id"`
+	Message   string `json:"message"`
+	CreateAt  int64  `json:"create_at"`
+}
+
+// Event is a single message from the Mattermost websocket.
+type Event struct {
+	Event     string                     `json:"event"`
+	Data      map[string]json.RawMessage `json:"data"`
+	Broadcast struct {
+		ChannelID string `json:"channel_id"`
+	} `json:"broadcast"`
+}
+
+// Post unmarshals the nested post payload carried by "posted" and
+// "post_edited" events. It's a no-op error for events that don't carry one.
+func (e Event) Post() (Post, error) {
+	raw, ok := e.Data["post"]
+	if !ok {
+		return Post{}, fmt.Errorf("event %q has no post data", e.Event)
+	}
+	// The post field is itself a JSON-encoded string, not a nested object.
+	var postJSON string
+	if err := json.Unmarshal(raw, &postJSON); err != nil {
+		return Post{}, fmt.Errorf("failed to unmarshal post string: %w", err)
+	}
+	var post Post
+	if err := json.Unmarshal([]byte(postJSON), &post); err != nil {
+		return Post{}, fmt.Errorf("failed to unmarshal post: %w", err)
+	}
+	return post, nil
+}
+
+// User is a Mattermost user, as returned by /api/v4/users/me.
+type User struct {
+	ID string `json:"id"`
+}
+
+// Client talks to a Mattermost server: it discovers team/channel membership
+// over REST and streams events over the websocket API.
+type Client struct {
+	baseURL  string
+	botToken string
+	client   *http.Client
+
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+// New creates a Client for the given Mattermost server, authenticating all
+// requests with botToken.
+func New(baseURL, botToken string) *Client {
+	return &Client{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		botToken: botToken,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Me returns the authenticated (bot) user.
+func (c *Client) Me() (User, error) {
+	var user User
+	if err := c.getJSON("/api/v4/users/me", &user); err != nil {
+		return User{}, fmt.Errorf("failed to get bot user: %w", err)
+	}
+	return user, nil
+}
+
+// Teams returns the teams the bot belongs to.
+func (c *Client) Teams() ([]Team, error) {
+	var teams []Team
+	if err := c.getJSON("/api/v4/users/me/teams", &teams); err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	return teams, nil
+}
+
+// Channels returns the channels the bot belongs to within a team.
+func (c *Client) Channels(teamID string) ([]Channel, error) {
+	var channels []Channel
+	path := fmt.Sprintf("/api/v4/users/me/teams/%s/channels", teamID)
+	if err := c.getJSON(path, &channels); err != nil {
+		return nil, fmt.Errorf("failed to list channels: %w", err)
+	}
+	return channels, nil
+}
+
+func (c *Client) getJSON(path string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.botToken)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %d - %s", path, resp.StatusCode, string(body))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Listen connects to the websocket API and calls onEvent for every event
+// received, blocking until stop is closed. It reconnects on disconnect, so a
+// closed connection does not make it return.
+func (c *Client) Listen(stop <-chan struct{}, onEvent func(Event)) error {
+	wsURL, err := c.websocketURL()
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+
+		if err := c.listenOnce(wsURL, stop, onEvent); err != nil {
+			log.Printf("mmclient: websocket error, reconnecting: %v", err)
+			time.Sleep(5 * time.Second)
+		}
+	}
+}
+
+func (c *Client) listenOnce(wsURL string, stop <-chan struct{}, onEvent func(Event)) error {
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{
+		"Authorization": []string{"Bearer " + c.botToken},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to dial websocket: %w", err)
+	}
+	defer conn.Close()
+
+	c.mu.Lock()
+	c.conn = conn
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			var event Event
+			if err := conn.ReadJSON(&event); err != nil {
+				log.Printf("mmclient: read error: %v", err)
+				return
+			}
+			if event.Event != "" {
+				onEvent(event)
+			}
+		}
+	}()
+
+	select {
+	case <-stop:
+		return nil
+	case <-done:
+		return fmt.Errorf("websocket connection closed")
+	}
+}
+
+func (c *Client) websocketURL() (string, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse mattermost url: %w", err)
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	u.Path = "/api/v4/websocket"
+	return u.String(), nil
+}
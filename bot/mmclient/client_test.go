@@ -0,0 +1,51 @@
+package mmclient
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEventPost(t *testing.T) {
+	// A realistic "posted" event: Data["post"] is a JSON-encoded string, not
+	// a nested object.
+	const raw = `{
+		"event": "posted",
+		"data": {
+			"post": "{\"id\":\"post1\",\"channel_id\":\"chan1\",\"user_id\":\"user1\",\"message\":\"hello\",\"create_at\":1700000000}",
+			"channel_display_name": "Town Square"
+		},
+		"broadcast": {"channel_id": "chan1"}
+	}`
+
+	var event Event
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		t.Fatalf("failed to unmarshal event: %v", err)
+	}
+
+	post, err := event.Post()
+	if err != nil {
+		t.Fatalf("Post() returned error: %v", err)
+	}
+
+	want := Post{ID: "post1", ChannelID: "chan1", UserID: "user1", Message: "hello", CreateAt: 1700000000}
+	if post != want {
+		t.Fatalf("Post() = %+v, want %+v", post, want)
+	}
+}
+
+func TestEventPostMissingData(t *testing.T) {
+	event := Event{Event: "typing", Data: map[string]json.RawMessage{}}
+	if _, err := event.Post(); err == nil {
+		t.Fatal("expected an error for an event with no post data")
+	}
+}
+
+func TestEventPostMalformed(t *testing.T) {
+	event := Event{
+		Event: "posted",
+		Data:  map[string]json.RawMessage{"post": json.RawMessage(`"not-valid-json-for-a-post"`)},
+	}
+	if _, err := event.Post(); err == nil {
+		t.Fatal("expected an error when the inner post string isn't valid JSON")
+	}
+}
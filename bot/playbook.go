@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PlaybookStep is one ordered step of a scripted lesson: a hint to show the
+// student, a regex that recognizes when they've completed it, and an
+// optional override of the tutor prompt fed to the LLM while they're on it.
+type PlaybookStep struct {
+	Title        string `yaml:"title"`
+	Hint         string `yaml:"hint"`
+	SuccessRegex string `yaml:"success_regex"`
+	LLMPrompt    string `yaml:"llm_prompt"`
+}
+
+// Playbook is a scripted, ordered checklist of steps layered on top of a
+// GameSession, e.g. playbooks/number-intro.yaml.
+type Playbook struct {
+	ID    string         `yaml:"id"`
+	Name  string         `yaml:"name"`
+	Game  string         `yaml:"game"`
+	Steps []PlaybookStep `yaml:"steps"`
+}
+
+// PlaybookRunService loads playbooks from disk and advances a GameSession's
+// step as the student's moves satisfy each step's success condition.
+type PlaybookRunService struct {
+	dir string
+
+	mu    sync.Mutex
+	cache map[string]*Playbook
+}
+
+// newPlaybookRunService loads playbooks from YAML files in dir, named
+// <id>.yaml.
+func newPlaybookRunService(dir string) *PlaybookRunService {
+	return &PlaybookRunService{dir: dir, cache: make(map[string]*Playbook)}
+}
+
+// Load returns the playbook with the given id, reading it from disk the
+// first time and caching it afterward.
+func (s *PlaybookRunService) Load(id string) (*Playbook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pb, ok := s.cache[id]; ok {
+		return pb, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, id+".yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read playbook %q: %w", id, err)
+	}
+
+	var pb Playbook
+	if err := yaml.Unmarshal(data, &pb); err != nil {
+		return nil, fmt.Errorf("failed to parse playbook %q: %w", id, err)
+	}
+	if len(pb.Steps) == 0 {
+		return nil, fmt.Errorf("playbook %q has no steps", id)
+	}
+
+	s.cache[id] = &pb
+	return &pb, nil
+}
+
+// Advance checks whether responseMessage satisfies the session's current
+// step and, if so, moves the session to the next one. It returns whether the
+// step advanced and the message to show the student (the next step's hint,
+// or a completion message on the last step).
+func (s *PlaybookRunService) Advance(session *GameSession, responseMessage string) (advanced bool, message string, err error) {
+	pb, err := s.Load(session.PlaybookID)
+	if err != nil {
+		return false, "", err
+	}
+	if session.StepIndex >= len(pb.Steps) {
+		return false, "", nil
+	}
+
+	step := pb.Steps[session.StepIndex]
+	if step.SuccessRegex == "" {
+		return false, "", nil
+	}
+	re, err := regexp.Compile(step.SuccessRegex)
+	if err != nil {
+		return false, "", fmt.Errorf("playbook %q has an invalid success_regex on step %d: %w", session.PlaybookID, session.StepIndex, err)
+	}
+	if !re.MatchString(responseMessage) {
+		return false, "", nil
+	}
+
+	session.StepIndex++
+	return true, s.stepMessage(pb, session.StepIndex), nil
+}
+
+// stepMessage renders the hint for a step index, or a completion message
+// once every step is done.
+func (s *PlaybookRunService) stepMessage(pb *Playbook, stepIndex int) string {
+	if stepIndex >= len(pb.Steps) {
+		return "🎉 Nice work — you've completed this playbook!"
+	}
+	step := pb.Steps[stepIndex]
+	return fmt.Sprintf("**Step %d: %s**\n%s", stepIndex+1, step.Title, step.Hint)
+}
+
+// CurrentPrompt returns the LLM prompt override for the session's current
+// step, or "" if there isn't one (or the playbook is already complete).
+func (s *PlaybookRunService) CurrentPrompt(session *GameSession) (string, error) {
+	pb, err := s.Load(session.PlaybookID)
+	if err != nil {
+		return "", err
+	}
+	if session.StepIndex >= len(pb.Steps) {
+		return "", nil
+	}
+	return pb.Steps[session.StepIndex].LLMPrompt, nil
+}
+
+// Reset moves a session back to the playbook's first step.
+func (s *PlaybookRunService) Reset(session *GameSession) {
+	session.StepIndex = 0
+}
+
+// Skip manually advances a session to the next step, bypassing its success
+// condition. Used by the `/game skip` administrative command.
+func (s *PlaybookRunService) Skip(session *GameSession) (string, error) {
+	pb, err := s.Load(session.PlaybookID)
+	if err != nil {
+		return "", err
+	}
+	if session.StepIndex < len(pb.Steps) {
+		session.StepIndex++
+	}
+	return s.stepMessage(pb, session.StepIndex), nil
+}
+
+// Intro renders the playbook's first step for the `/game playbook <name>`
+// start response.
+func (s *PlaybookRunService) Intro(pb *Playbook) string {
+	return fmt.Sprintf("**📘 Starting playbook: %s**\n\n%s", pb.Name, s.stepMessage(pb, 0))
+}
+
+// startPlaybook starts the playbook's underlying game and creates a session
+// tracking progress through it.
+func (b *Bot) startPlaybook(cmd SlashCommandRequest, playbookID string) (string, error) {
+	pb, err := b.playbooks.Load(playbookID)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := b.startGame(GameConfig{Name: pb.Game})
+	if err != nil {
+		return "", err
+	}
+	if response.Error != "" {
+		return "", fmt.Errorf("%s", response.Error)
+	}
+
+	session := &GameSession{
+		GameName:   pb.Game,
+		Active:     true,
+		StartedAt:  time.Now().Unix(),
+		StartedBy:  cmd.UserID,
+		Config:     GameConfig{Name: pb.Game},
+		PlaybookID: playbookID,
+		StepIndex:  0,
+	}
+	if err := b.sessions.Put(cmd.ChannelID, session); err != nil {
+		return "", err
+	}
+
+	return b.playbooks.Intro(pb), nil
+}
+
+// skipPlaybookStep manually advances the channel's active playbook, bypassing
+// its success condition.
+func (b *Bot) skipPlaybookStep(channelID string) (string, error) {
+	session, err := b.sessions.Get(channelID)
+	if err != nil {
+		return "", err
+	}
+	if session == nil || session.PlaybookID == "" {
+		return "", fmt.Errorf("no active playbook in this channel")
+	}
+
+	msg, err := b.playbooks.Skip(session)
+	if err != nil {
+		return "", err
+	}
+	if err := b.sessions.Put(channelID, session); err != nil {
+		return "", err
+	}
+	return msg, nil
+}
+
+// resetPlaybookStep moves the channel's active playbook back to its first
+// step.
+func (b *Bot) resetPlaybookStep(channelID string) (string, error) {
+	session, err := b.sessions.Get(channelID)
+	if err != nil {
+		return "", err
+	}
+	if session == nil || session.PlaybookID == "" {
+		return "", fmt.Errorf("no active playbook in this channel")
+	}
+
+	b.playbooks.Reset(session)
+	if err := b.sessions.Put(channelID, session); err != nil {
+		return "", err
+	}
+	return "🔁 Playbook reset to step 1.", nil
+}
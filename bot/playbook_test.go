@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestPlaybook(t *testing.T, dir string) {
+	t.Helper()
+	const doc = `
+id: test-playbook
+name: "Test playbook"
+game: number
+steps:
+  - title: Step one
+    hint: Do the first thing.
+    success_regex: '(?i)too (high|low)'
+    llm_prompt: first step prompt
+  - title: Step two
+    hint: Do the second thing.
+    success_regex: '(?i)correct'
+    llm_prompt: second step prompt
+`
+	if err := os.WriteFile(filepath.Join(dir, "test-playbook.yaml"), []byte(doc), 0o644); err != nil {
+		t.Fatalf("failed to write test playbook: %v", err)
+	}
+}
+
+func TestPlaybookRunServiceAdvance(t *testing.T) {
+	dir := t.TempDir()
+	writeTestPlaybook(t, dir)
+	svc := newPlaybookRunService(dir)
+
+	session := &GameSession{PlaybookID: "test-playbook"}
+
+	advanced, _, err := svc.Advance(session, "Nope, try again.")
+	if err != nil {
+		t.Fatalf("Advance returned error: %v", err)
+	}
+	if advanced {
+		t.Fatal("Advance should not advance when the response doesn't match success_regex")
+	}
+	if session.StepIndex != 0 {
+		t.Fatalf("StepIndex = %d, want 0", session.StepIndex)
+	}
+
+	advanced, message, err := svc.Advance(session, "Too high!")
+	if err != nil {
+		t.Fatalf("Advance returned error: %v", err)
+	}
+	if !advanced {
+		t.Fatal("Advance should advance when the response matches success_regex")
+	}
+	if session.StepIndex != 1 {
+		t.Fatalf("StepIndex = %d, want 1", session.StepIndex)
+	}
+	if message == "" {
+		t.Fatal("Advance should return the next step's hint message")
+	}
+
+	advanced, _, err = svc.Advance(session, "Correct!")
+	if err != nil {
+		t.Fatalf("Advance returned error: %v", err)
+	}
+	if !advanced || session.StepIndex != 2 {
+		t.Fatalf("expected Advance to complete the playbook, got advanced=%v StepIndex=%d", advanced, session.StepIndex)
+	}
+
+	advanced, _, err = svc.Advance(session, "Correct!")
+	if err != nil {
+		t.Fatalf("Advance returned error: %v", err)
+	}
+	if advanced {
+		t.Fatal("Advance should not advance past the last step")
+	}
+}
+
+func TestPlaybookRunServiceLoadUnknown(t *testing.T) {
+	svc := newPlaybookRunService(t.TempDir())
+	if _, err := svc.Load("does-not-exist"); err == nil {
+		t.Fatal("expected an error loading a playbook that doesn't exist")
+	}
+}
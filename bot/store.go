@@ -0,0 +1,203 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SessionStore persists GameSessions so that active games survive a bot
+// restart. Implementations must be safe for concurrent use: Get and List
+// return a session's own copy, not a pointer into the store's internal
+// state, so callers can freely mutate the result and must call Put to save
+// any changes back.
+type SessionStore interface {
+	Get(channelID string) (*GameSession, error)
+	Put(channelID string, session *GameSession) error
+	Delete(channelID string) error
+	List() ([]*GameSession, error)
+}
+
+// newSessionStore opens the SessionStore configured by SESSION_DB_PATH. An
+// empty path (the default for tests and local development) gives an
+// in-memory store that doesn't survive a restart.
+func newSessionStore(dbPath string) (SessionStore, error) {
+	if dbPath == "" {
+		return newMemorySessionStore(), nil
+	}
+	return newSQLiteSessionStore(dbPath)
+}
+
+// memorySessionStore is a SessionStore backed by a plain map. It's the
+// default when SESSION_DB_PATH isn't set, and what tests use so they don't
+// need a database on disk.
+type memorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*GameSession
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{sessions: make(map[string]*GameSession)}
+}
+
+func (s *memorySessionStore) Get(channelID string) (*GameSession, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, ok := s.sessions[channelID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (s *memorySessionStore) Put(channelID string, session *GameSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *session
+	s.sessions[channelID] = &copied
+	return nil
+}
+
+func (s *memorySessionStore) Delete(channelID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, channelID)
+	return nil
+}
+
+func (s *memorySessionStore) List() ([]*GameSession, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sessions := make([]*GameSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		copied := *session
+		sessions = append(sessions, &copied)
+	}
+	return sessions, nil
+}
+
+// sqliteSessionStore is a SessionStore backed by a SQLite database, so
+// sessions survive a bot restart.
+type sqliteSessionStore struct {
+	db *sql.DB
+}
+
+// newSQLiteSessionStore opens (creating if necessary) a SQLite database at
+// dbPath and ensures the sessions table exists.
+func newSQLiteSessionStore(dbPath string) (*sqliteSessionStore, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session db: %w", err)
+	}
+
+	const schema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	channel_id TEXT PRIMARY KEY,
+	game_name TEXT NOT NULL,
+	active INTEGER NOT NULL,
+	started_at INTEGER NOT NULL,
+	started_by TEXT NOT NULL,
+	config_json TEXT NOT NULL DEFAULT '{}',
+	playbook_id TEXT NOT NULL DEFAULT '',
+	step_index INTEGER NOT NULL DEFAULT 0
+)`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create sessions table: %w", err)
+	}
+
+	return &sqliteSessionStore{db: db}, nil
+}
+
+func (s *sqliteSessionStore) Get(channelID string) (*GameSession, error) {
+	row := s.db.QueryRow(`SELECT game_name, active, started_at, started_by, config_json, playbook_id, step_index FROM sessions WHERE channel_id = ?`, channelID)
+	session, err := scanSession(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return session, nil
+}
+
+func (s *sqliteSessionStore) Put(channelID string, session *GameSession) error {
+	configJSON, err := json.Marshal(session.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session config: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+INSERT INTO sessions (channel_id, game_name, active, started_at, started_by, config_json, playbook_id, step_index)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(channel_id) DO UPDATE SET
+	game_name = excluded.game_name,
+	active = excluded.active,
+	started_at = excluded.started_at,
+	started_by = excluded.started_by,
+	config_json = excluded.config_json,
+	playbook_id = excluded.playbook_id,
+	step_index = excluded.step_index`,
+		channelID, session.GameName, boolToInt(session.Active), session.StartedAt, session.StartedBy, string(configJSON), session.PlaybookID, session.StepIndex)
+	if err != nil {
+		return fmt.Errorf("failed to put session: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteSessionStore) Delete(channelID string) error {
+	if _, err := s.db.Exec(`DELETE FROM sessions WHERE channel_id = ?`, channelID); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteSessionStore) List() ([]*GameSession, error) {
+	rows, err := s.db.Query(`SELECT game_name, active, started_at, started_by, config_json, playbook_id, step_index FROM sessions`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*GameSession
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSession(row rowScanner) (*GameSession, error) {
+	var (
+		session    GameSession
+		activeInt  int
+		configJSON string
+	)
+	if err := row.Scan(&session.GameName, &activeInt, &session.StartedAt, &session.StartedBy, &configJSON, &session.PlaybookID, &session.StepIndex); err != nil {
+		return nil, err
+	}
+	session.Active = activeInt != 0
+	if err := json.Unmarshal([]byte(configJSON), &session.Config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session config: %w", err)
+	}
+	return &session, nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func testSessionStoreRoundTrip(t *testing.T, store SessionStore) {
+	t.Helper()
+
+	if got, err := store.Get("chan-1"); err != nil || got != nil {
+		t.Fatalf("Get on empty store = (%v, %v), want (nil, nil)", got, err)
+	}
+
+	session := &GameSession{
+		GameName:   "number",
+		Active:     true,
+		StartedAt:  1234,
+		StartedBy:  "user-1",
+		Config:     GameConfig{Name: "number", Mode: "timed", MaxPoints: 50},
+		PlaybookID: "number-intro",
+		StepIndex:  1,
+	}
+	if err := store.Put("chan-1", session); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+
+	got, err := store.Get("chan-1")
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if got == nil {
+		t.Fatal("Get returned nil after Put")
+	}
+	if *got != *session {
+		t.Fatalf("Get = %+v, want %+v", *got, *session)
+	}
+
+	// Mutating the returned session must not affect the stored copy.
+	got.StepIndex = 99
+	reGot, err := store.Get("chan-1")
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if reGot.StepIndex != 1 {
+		t.Fatalf("StepIndex = %d after mutating a previously returned session, want 1 (store should be isolated)", reGot.StepIndex)
+	}
+
+	session.StepIndex = 2
+	if err := store.Put("chan-1", session); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	got, err = store.Get("chan-1")
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if got.StepIndex != 2 {
+		t.Fatalf("StepIndex = %d after re-Put, want 2", got.StepIndex)
+	}
+
+	if err := store.Put("chan-2", &GameSession{GameName: "maze", Active: true}); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	all, err := store.List()
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("List returned %d sessions, want 2", len(all))
+	}
+
+	if err := store.Delete("chan-1"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if got, err := store.Get("chan-1"); err != nil || got != nil {
+		t.Fatalf("Get after Delete = (%v, %v), want (nil, nil)", got, err)
+	}
+	all, err = store.List()
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("List after Delete returned %d sessions, want 1", len(all))
+	}
+}
+
+func TestMemorySessionStoreRoundTrip(t *testing.T) {
+	testSessionStoreRoundTrip(t, newMemorySessionStore())
+}
+
+func TestSQLiteSessionStoreRoundTrip(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "sessions.db")
+	store, err := newSQLiteSessionStore(dbPath)
+	if err != nil {
+		t.Fatalf("newSQLiteSessionStore returned error: %v", err)
+	}
+	testSessionStoreRoundTrip(t, store)
+}
@@ -0,0 +1,369 @@
+// Package llm wraps calls to OpenRouter with the rate limiting, retry, and
+// circuit breaking discipline a shared classroom deployment needs: a single
+// confused channel shouldn't be able to blow the whole server's quota.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const openRouterURL = "https://openrouter.ai/api/v1/chat/completions"
+
+// breakerCooldown is how long the circuit stays open after it trips, before
+// the next request is allowed to probe OpenRouter again.
+const breakerCooldown = 30 * time.Second
+
+// ErrBreakerOpen is returned while the circuit breaker is open.
+var ErrBreakerOpen = errors.New("AI tutor is resting, please try again in a bit")
+
+// Config configures a Client.
+type Config struct {
+	APIKey string
+	Model  string
+
+	// RPS and Burst size the global and per-channel token buckets.
+	RPS   float64
+	Burst int
+
+	// BreakerThreshold is how many consecutive upstream failures trip the
+	// circuit breaker.
+	BreakerThreshold int
+}
+
+// Metrics is a snapshot of a Client's request counters, suitable for
+// exposing on a /metrics endpoint.
+type Metrics struct {
+	Requests    uint64
+	Retries     uint64
+	Throttled   uint64
+	BreakerOpen uint64
+}
+
+// Client calls OpenRouter's chat completions API with per-channel and global
+// rate limiting, retry with backoff on 429s and 5xxs, and a circuit breaker
+// that trips after repeated upstream failures.
+type Client struct {
+	config Config
+	http   *http.Client
+
+	globalLimiter   *rate.Limiter
+	channelLimiters sync.Map // channelID -> *rate.Limiter
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+	breakerOpenUntil    time.Time
+
+	// rateMu guards the most recently observed rate-limit headers, so we can
+	// proactively wait out a window OpenRouter told us is exhausted instead
+	// of discovering it via a 429.
+	rateMu        sync.Mutex
+	rateRemaining int // -1 until OpenRouter has told us a value
+	rateResetAt   time.Time
+
+	metrics Metrics
+}
+
+// New creates a Client. RPS/Burst/BreakerThreshold fall back to sane
+// defaults if left unset.
+func New(config Config) *Client {
+	if config.RPS <= 0 {
+		config.RPS = 1
+	}
+	if config.Burst <= 0 {
+		config.Burst = 1
+	}
+	if config.BreakerThreshold <= 0 {
+		config.BreakerThreshold = 5
+	}
+	return &Client{
+		config:        config,
+		http:          &http.Client{Timeout: 30 * time.Second},
+		globalLimiter: rate.NewLimiter(rate.Limit(config.RPS), config.Burst),
+		rateRemaining: -1,
+	}
+}
+
+// Metrics returns a snapshot of the client's counters.
+func (c *Client) Metrics() Metrics {
+	return Metrics{
+		Requests:    atomic.LoadUint64(&c.metrics.Requests),
+		Retries:     atomic.LoadUint64(&c.metrics.Retries),
+		Throttled:   atomic.LoadUint64(&c.metrics.Throttled),
+		BreakerOpen: atomic.LoadUint64(&c.metrics.BreakerOpen),
+	}
+}
+
+// Complete asks OpenRouter for a chat completion, waiting on the global and
+// per-channel rate limiters first, retrying with backoff on throttling or
+// server errors, and short-circuiting with ErrBreakerOpen once the breaker
+// has tripped.
+func (c *Client) Complete(ctx context.Context, channelID, systemPrompt, userMessage string) (string, error) {
+	if c.breakerOpen() {
+		return "", ErrBreakerOpen
+	}
+
+	if err := c.globalLimiter.Wait(ctx); err != nil {
+		return "", err
+	}
+	if err := c.channelLimiter(channelID).Wait(ctx); err != nil {
+		return "", err
+	}
+
+	const maxAttempts = 4
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if wait := c.rateWindowExhaustedWait(); wait > 0 {
+			atomic.AddUint64(&c.metrics.Throttled, 1)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+
+		atomic.AddUint64(&c.metrics.Requests, 1)
+
+		content, retryAfter, err := c.doRequest(ctx, systemPrompt, userMessage)
+		if err == nil {
+			c.recordResult(true)
+			return content, nil
+		}
+		lastErr = err
+
+		var rle *rateLimitError
+		if errors.As(err, &rle) {
+			atomic.AddUint64(&c.metrics.Throttled, 1)
+		}
+
+		// Only genuine upstream failures should count against the breaker.
+		// 429s are expected throttling (handled by retry/backoff below) and
+		// local marshal/parse errors say nothing about OpenRouter's health.
+		var se *serverError
+		if errors.As(err, &se) {
+			c.recordResult(false)
+		}
+		if c.breakerOpen() {
+			return "", ErrBreakerOpen
+		}
+		if attempt == maxAttempts-1 {
+			break
+		}
+
+		atomic.AddUint64(&c.metrics.Retries, 1)
+		wait := retryAfter
+		if wait <= 0 {
+			wait = backoff(attempt)
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	return "", fmt.Errorf("openrouter request failed after retries: %w", lastErr)
+}
+
+func (c *Client) channelLimiter(channelID string) *rate.Limiter {
+	if l, ok := c.channelLimiters.Load(channelID); ok {
+		return l.(*rate.Limiter)
+	}
+	l := rate.NewLimiter(rate.Limit(c.config.RPS), c.config.Burst)
+	actual, _ := c.channelLimiters.LoadOrStore(channelID, l)
+	return actual.(*rate.Limiter)
+}
+
+// rateWindowExhaustedWait returns how long to wait before the next request
+// if OpenRouter's last response said we've used up our X-RateLimit-Remaining
+// for the current window, so we throttle ourselves before hitting a 429
+// instead of after.
+func (c *Client) rateWindowExhaustedWait() time.Duration {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+	if c.rateRemaining != 0 {
+		return 0
+	}
+	return time.Until(c.rateResetAt)
+}
+
+// recordRateHeaders remembers the X-RateLimit-Remaining/Reset OpenRouter sent
+// on the last response, regardless of status code, so the next request can
+// check rateWindowExhaustedWait before it's sent.
+func (c *Client) recordRateHeaders(h http.Header) {
+	remaining := h.Get("X-RateLimit-Remaining")
+	if remaining == "" {
+		return
+	}
+	n, err := strconv.Atoi(remaining)
+	if err != nil {
+		return
+	}
+
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+	c.rateRemaining = n
+	if reset := h.Get("X-RateLimit-Reset"); reset != "" {
+		if unix, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			c.rateResetAt = time.Unix(unix, 0)
+		}
+	}
+}
+
+func (c *Client) breakerOpen() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Now().Before(c.breakerOpenUntil)
+}
+
+func (c *Client) recordResult(success bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if success {
+		c.consecutiveFailures = 0
+		return
+	}
+	c.consecutiveFailures++
+	if c.consecutiveFailures >= c.config.BreakerThreshold {
+		c.breakerOpenUntil = time.Now().Add(breakerCooldown)
+		atomic.AddUint64(&c.metrics.BreakerOpen, 1)
+	}
+}
+
+// rateLimitError marks a failure as a 429 so callers can distinguish
+// throttling from other upstream errors.
+type rateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (e *rateLimitError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s", e.retryAfter)
+}
+
+// serverError marks a failure as a genuine upstream (5xx) error, as opposed
+// to throttling or a local marshal/parse failure, so the breaker only trips
+// on real OpenRouter outages.
+type serverError struct {
+	statusCode int
+	body       string
+}
+
+func (e *serverError) Error() string {
+	return fmt.Sprintf("openrouter returned %d: %s", e.statusCode, e.body)
+}
+
+// doRequest makes a single attempt against OpenRouter. It returns a non-zero
+// retryAfter when the response told us how long to wait before trying again.
+func (c *Client) doRequest(ctx context.Context, systemPrompt, userMessage string) (content string, retryAfter time.Duration, err error) {
+	reqBody := openRouterRequest{
+		Model: c.config.Model,
+		Messages: []openRouterMsg{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userMessage},
+		},
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, openRouterURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	req.Header.Set("HTTP-Referer", "https://github.com/shishberg/mattergame")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to call OpenRouter: %w", err)
+	}
+	defer resp.Body.Close()
+	c.recordRateHeaders(resp.Header)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := retryAfterFromHeaders(resp.Header)
+		return "", retryAfter, &rateLimitError{retryAfter: retryAfter}
+	}
+	if resp.StatusCode >= 500 {
+		return "", 0, &serverError{statusCode: resp.StatusCode, body: string(body)}
+	}
+
+	var llmResp openRouterResponse
+	if err := json.Unmarshal(body, &llmResp); err != nil {
+		return "", 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if llmResp.Error != nil {
+		return "", 0, fmt.Errorf("openrouter error: %s", llmResp.Error.Message)
+	}
+	if len(llmResp.Choices) == 0 {
+		return "", 0, fmt.Errorf("no response from AI")
+	}
+
+	return llmResp.Choices[0].Message.Content, 0, nil
+}
+
+// retryAfterFromHeaders reads Retry-After (seconds) or falls back to
+// X-RateLimit-Reset (unix seconds) when OpenRouter throttles us.
+func retryAfterFromHeaders(h http.Header) time.Duration {
+	if ra := h.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+	}
+	if reset := h.Get("X-RateLimit-Reset"); reset != "" {
+		if unix, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			if wait := time.Until(time.Unix(unix, 0)); wait > 0 {
+				return wait
+			}
+		}
+	}
+	return 0
+}
+
+// backoff is exponential with full jitter: attempt 0 waits up to 500ms,
+// attempt 1 up to 1s, and so on.
+func backoff(attempt int) time.Duration {
+	base := time.Duration(1<<uint(attempt)) * 500 * time.Millisecond
+	return time.Duration(rand.Int63n(int64(base)))
+}
+
+type openRouterRequest struct {
+	Model    string          `json:"model"`
+	Messages []openRouterMsg `json:"messages"`
+}
+
+type openRouterMsg struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openRouterResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
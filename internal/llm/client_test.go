@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRecordResultTripsBreakerAfterThreshold(t *testing.T) {
+	c := New(Config{BreakerThreshold: 3})
+
+	for i := 0; i < 2; i++ {
+		c.recordResult(false)
+		if c.breakerOpen() {
+			t.Fatalf("breaker opened after only %d failures", i+1)
+		}
+	}
+
+	c.recordResult(false)
+	if !c.breakerOpen() {
+		t.Fatal("expected breaker to be open after reaching BreakerThreshold")
+	}
+}
+
+func TestRecordResultSuccessResetsFailures(t *testing.T) {
+	c := New(Config{BreakerThreshold: 3})
+
+	c.recordResult(false)
+	c.recordResult(false)
+	c.recordResult(true)
+
+	if c.consecutiveFailures != 0 {
+		t.Fatalf("consecutiveFailures = %d after a success, want 0", c.consecutiveFailures)
+	}
+
+	c.recordResult(false)
+	c.recordResult(false)
+	if c.breakerOpen() {
+		t.Fatal("breaker should not open until BreakerThreshold consecutive failures since the reset")
+	}
+}
+
+func TestBackoffIsBoundedAndIncreasesWithAttempt(t *testing.T) {
+	for attempt := 0; attempt < 5; attempt++ {
+		max := time.Duration(1<<uint(attempt)) * 500 * time.Millisecond
+		for i := 0; i < 20; i++ {
+			if d := backoff(attempt); d < 0 || d >= max {
+				t.Fatalf("backoff(%d) = %s, want in [0, %s)", attempt, d, max)
+			}
+		}
+	}
+}
+
+func TestRetryAfterFromHeadersRetryAfterSeconds(t *testing.T) {
+	h := http.Header{}
+	h.Set("Retry-After", "5")
+	if got := retryAfterFromHeaders(h); got != 5*time.Second {
+		t.Fatalf("retryAfterFromHeaders = %s, want 5s", got)
+	}
+}
+
+func TestRetryAfterFromHeadersNoneSet(t *testing.T) {
+	if got := retryAfterFromHeaders(http.Header{}); got != 0 {
+		t.Fatalf("retryAfterFromHeaders = %s, want 0", got)
+	}
+}
+
+func TestRateWindowExhaustedWait(t *testing.T) {
+	c := New(Config{})
+
+	if wait := c.rateWindowExhaustedWait(); wait != 0 {
+		t.Fatalf("rateWindowExhaustedWait = %s before any headers seen, want 0", wait)
+	}
+
+	h := http.Header{}
+	h.Set("X-RateLimit-Remaining", "0")
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+	c.recordRateHeaders(h)
+
+	wait := c.rateWindowExhaustedWait()
+	if wait <= 0 || wait > time.Minute {
+		t.Fatalf("rateWindowExhaustedWait = %s, want roughly 1m after remaining=0", wait)
+	}
+
+	h.Set("X-RateLimit-Remaining", "10")
+	c.recordRateHeaders(h)
+	if wait := c.rateWindowExhaustedWait(); wait != 0 {
+		t.Fatalf("rateWindowExhaustedWait = %s once remaining > 0, want 0", wait)
+	}
+}
@@ -0,0 +1,198 @@
+// Package loadtest simulates students exercising the bot, so maintainers can
+// benchmark the Python game server and reproduce the LLM rate-limit paths
+// without recruiting live testers.
+package loadtest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Config describes a loadtest run, e.g. `/game loadtest sessions=20 moves=15
+// game=number rate=2/s`.
+type Config struct {
+	Game     string
+	Sessions int
+	Moves    int
+	Rate     float64 // requests per second, shared across all synthetic sessions
+
+	// MoveScript is the sequence of moves each session sends. If shorter
+	// than Moves, it repeats.
+	MoveScript []string
+}
+
+// StartFunc starts one synthetic session's game. businessErr is the
+// GameResponse's Error field, distinct from transport failures.
+type StartFunc func(game string) (businessErr string, err error)
+
+// MoveFunc sends one synthetic move.
+type MoveFunc func(game, move string) (businessErr string, err error)
+
+// Result summarizes a completed loadtest run.
+type Result struct {
+	Config       Config
+	Duration     time.Duration
+	StartLatency Histogram
+	MoveLatency  Histogram
+	Errors       map[string]int // businessErr or "transport: <err>" -> count
+}
+
+// Histogram is a simple latency summary; percentiles are computed from all
+// observed samples rather than approximated buckets, which is fine at
+// loadtest scale.
+type Histogram struct {
+	Count int
+	Min   time.Duration
+	Max   time.Duration
+	Mean  time.Duration
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+}
+
+func newHistogram(samples []time.Duration) Histogram {
+	if len(samples) == 0 {
+		return Histogram{}
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, s := range sorted {
+		sum += s
+	}
+
+	return Histogram{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Max:   sorted[len(sorted)-1],
+		Mean:  sum / time.Duration(len(sorted)),
+		P50:   percentile(sorted, 50),
+		P95:   percentile(sorted, 95),
+		P99:   percentile(sorted, 99),
+	}
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := (p * len(sorted)) / 100
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// Runner fans synthetic sessions out over goroutines, rate limited by a
+// single shared token bucket so the run approximates a real burst of
+// students rather than hammering the server as fast as possible.
+type Runner struct {
+	limiter *rate.Limiter
+}
+
+// NewRunner creates a Runner that allows rps requests per second, bursting
+// up to rps rounded down to a whole request (at least 1).
+func NewRunner(rps float64) *Runner {
+	if rps <= 0 {
+		rps = 1
+	}
+	burst := int(rps)
+	if burst < 1 {
+		burst = 1
+	}
+	return &Runner{limiter: rate.NewLimiter(rate.Limit(rps), burst)}
+}
+
+// Run starts cfg.Sessions synthetic conversations in parallel, each sending
+// cfg.Moves moves from cfg.MoveScript (repeating if necessary), and returns
+// aggregated latency and error statistics.
+func (r *Runner) Run(ctx context.Context, cfg Config, start StartFunc, move MoveFunc) *Result {
+	begin := time.Now()
+
+	var mu sync.Mutex
+	startLatencies := make([]time.Duration, 0, cfg.Sessions)
+	moveLatencies := make([]time.Duration, 0, cfg.Sessions*cfg.Moves)
+	errors := make(map[string]int)
+
+	record := func(businessErr string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case err != nil:
+			errors["transport: "+err.Error()]++
+		case businessErr != "":
+			errors[businessErr]++
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.Sessions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.runSession(ctx, cfg, start, move, &mu, &startLatencies, &moveLatencies, record)
+		}()
+	}
+	wg.Wait()
+
+	return &Result{
+		Config:       cfg,
+		Duration:     time.Since(begin),
+		StartLatency: newHistogram(startLatencies),
+		MoveLatency:  newHistogram(moveLatencies),
+		Errors:       errors,
+	}
+}
+
+func (r *Runner) runSession(
+	ctx context.Context,
+	cfg Config,
+	start StartFunc,
+	move MoveFunc,
+	mu *sync.Mutex,
+	startLatencies *[]time.Duration,
+	moveLatencies *[]time.Duration,
+	record func(string, error),
+) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		record("", err)
+		return
+	}
+	t0 := time.Now()
+	businessErr, err := start(cfg.Game)
+	latency := time.Since(t0)
+	mu.Lock()
+	*startLatencies = append(*startLatencies, latency)
+	mu.Unlock()
+	record(businessErr, err)
+	if err != nil {
+		return
+	}
+
+	for i := 0; i < cfg.Moves; i++ {
+		if err := r.limiter.Wait(ctx); err != nil {
+			record("", err)
+			return
+		}
+		m := moveAt(cfg.MoveScript, i)
+		t0 := time.Now()
+		businessErr, err := move(cfg.Game, m)
+		latency := time.Since(t0)
+		mu.Lock()
+		*moveLatencies = append(*moveLatencies, latency)
+		mu.Unlock()
+		record(businessErr, err)
+	}
+}
+
+func moveAt(script []string, i int) string {
+	if len(script) == 0 {
+		return "move"
+	}
+	return script[i%len(script)]
+}
@@ -0,0 +1,71 @@
+package loadtest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewHistogram(t *testing.T) {
+	samples := []time.Duration{
+		500 * time.Millisecond,
+		100 * time.Millisecond,
+		300 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+	}
+
+	h := newHistogram(samples)
+
+	if h.Count != 5 {
+		t.Fatalf("Count = %d, want 5", h.Count)
+	}
+	if h.Min != 100*time.Millisecond {
+		t.Fatalf("Min = %s, want 100ms", h.Min)
+	}
+	if h.Max != 500*time.Millisecond {
+		t.Fatalf("Max = %s, want 500ms", h.Max)
+	}
+	if h.Mean != 300*time.Millisecond {
+		t.Fatalf("Mean = %s, want 300ms", h.Mean)
+	}
+}
+
+func TestNewHistogramEmpty(t *testing.T) {
+	if h := newHistogram(nil); h.Count != 0 {
+		t.Fatalf("Count = %d, want 0 for no samples", h.Count)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{
+		1 * time.Millisecond,
+		2 * time.Millisecond,
+		3 * time.Millisecond,
+		4 * time.Millisecond,
+		5 * time.Millisecond,
+	}
+
+	if got := percentile(sorted, 0); got != 1*time.Millisecond {
+		t.Fatalf("percentile(0) = %s, want 1ms", got)
+	}
+	if got := percentile(sorted, 99); got != 5*time.Millisecond {
+		t.Fatalf("percentile(99) = %s, want 5ms (the last sample)", got)
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Fatalf("percentile on empty input = %s, want 0", got)
+	}
+}
+
+func TestMoveAt(t *testing.T) {
+	if got := moveAt(nil, 0); got != "move" {
+		t.Fatalf("moveAt(nil, 0) = %q, want the default move literal", got)
+	}
+
+	script := []string{"guess 10", "guess 50", "guess 75"}
+	if got := moveAt(script, 1); got != "guess 50" {
+		t.Fatalf("moveAt(script, 1) = %q, want %q", got, "guess 50")
+	}
+	if got := moveAt(script, 3); got != "guess 10" {
+		t.Fatalf("moveAt(script, 3) = %q, want the script to wrap around", got)
+	}
+}